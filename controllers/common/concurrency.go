@@ -0,0 +1,98 @@
+package common
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultMaxConcurrentScopeOps = 4
+	maxRetries                   = 3
+	backoffBase                  = 200 * time.Millisecond
+)
+
+// maxConcurrentScopeOps returns the configured concurrency cap for scope/consumer
+// operations against DigDir, falling back to a sane default when unset.
+func (s scope) maxConcurrentScopeOps() int {
+	n := s.Tx.Config.DigDir.MaxConcurrentScopeOps
+	if n < 1 {
+		return defaultMaxConcurrentScopeOps
+	}
+	return n
+}
+
+// forEachBounded runs fn once per item with at most maxConcurrency goroutines in
+// flight, collecting every error instead of aborting on the first one - a single
+// failing consumer ACL update should not block updates for unrelated scopes.
+func forEachBounded(items int, maxConcurrency int, fn func(i int) error) error {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+
+	var g errgroup.Group
+	sem := make(chan struct{}, maxConcurrency)
+	errs := make([]error, items)
+
+	for i := 0; i < items; i++ {
+		i := i
+		sem <- struct{}{}
+		g.Go(func() error {
+			defer func() { <-sem }()
+			errs[i] = fn(i)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	var result *multierror.Error
+	for _, err := range errs {
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	return result.ErrorOrNil()
+}
+
+// statusCoder is implemented by DigdirClient errors that carry the HTTP status code
+// of the failed request, letting withRetry distinguish transient (429/5xx) failures
+// from permanent ones.
+type statusCoder interface {
+	StatusCode() int
+}
+
+func isRetryable(err error) bool {
+	var sc statusCoder
+	if errors.As(err, &sc) {
+		code := sc.StatusCode()
+		return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// withRetry retries fn with exponential backoff and jitter on retryable (429/5xx)
+// errors from DigDir, giving up after maxRetries attempts. onRetry, if set, is
+// called once per retried attempt so callers can record a "retry" metric.
+func withRetry(fn func() error, onRetry func()) error {
+	var err error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		err = fn()
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+		if attempt == maxRetries-1 {
+			break
+		}
+		if onRetry != nil {
+			onRetry()
+		}
+		backoff := backoffBase * time.Duration(1<<attempt)
+		jitter := time.Duration(rand.Int63n(int64(backoff)))
+		time.Sleep(backoff + jitter)
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package common
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStatusErr struct {
+	code int
+}
+
+func (e fakeStatusErr) Error() string {
+	return "fake status error"
+}
+
+func (e fakeStatusErr) StatusCode() int {
+	return e.code
+}
+
+func TestIsRetryable(t *testing.T) {
+	t.Run("429 is retryable", func(t *testing.T) {
+		assert.True(t, isRetryable(fakeStatusErr{code: 429}))
+	})
+	t.Run("5xx is retryable", func(t *testing.T) {
+		assert.True(t, isRetryable(fakeStatusErr{code: 503}))
+	})
+	t.Run("4xx other than 429 is not retryable", func(t *testing.T) {
+		assert.False(t, isRetryable(fakeStatusErr{code: 404}))
+	})
+	t.Run("plain error is not retryable", func(t *testing.T) {
+		assert.False(t, isRetryable(errors.New("boom")))
+	})
+}
+
+func TestForEachBounded(t *testing.T) {
+	t.Run("runs fn for every item", func(t *testing.T) {
+		var calls int32
+		err := forEachBounded(10, 3, func(i int) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 10, calls)
+	})
+
+	t.Run("aggregates errors instead of stopping at the first one", func(t *testing.T) {
+		err := forEachBounded(5, 2, func(i int) error {
+			if i%2 == 0 {
+				return errors.New("failed")
+			}
+			return nil
+		})
+		assert.Error(t, err)
+		var merr *multierror.Error
+		assert.ErrorAs(t, err, &merr)
+		assert.Len(t, merr.Errors, 3)
+	})
+}
+
+func TestWithRetry(t *testing.T) {
+	t.Run("returns a non-retryable error without retrying", func(t *testing.T) {
+		var attempts int
+		err := withRetry(func() error {
+			attempts++
+			return errors.New("permanent failure")
+		}, func() { t.Fatal("onRetry should not be called for non-retryable errors") })
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("retries a retryable error until it succeeds", func(t *testing.T) {
+		var attempts, retries int
+		err := withRetry(func() error {
+			attempts++
+			if attempts < 2 {
+				return fakeStatusErr{code: 503}
+			}
+			return nil
+		}, func() { retries++ })
+		assert.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+		assert.Equal(t, 1, retries)
+	})
+
+	t.Run("gives up after maxRetries attempts", func(t *testing.T) {
+		var attempts int
+		err := withRetry(func() error {
+			attempts++
+			return fakeStatusErr{code: 503}
+		}, nil)
+		assert.Error(t, err)
+		assert.Equal(t, maxRetries, attempts)
+	})
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanReport_addScope(t *testing.T) {
+	report := newPlanReport()
+
+	report.addScope("scope-a", "create")
+	report.addScope("scope-b", "update")
+	report.addScope("scope-c", "activate")
+	report.addScope("scope-d", "deactivate")
+
+	assert.Equal(t, 1, report.ScopesCreated)
+	assert.Equal(t, 1, report.ScopesUpdated)
+	assert.Equal(t, 1, report.ScopesActivated)
+	assert.Equal(t, 1, report.ScopesDeactivated)
+	assert.Len(t, report.ScopeActions, 4)
+}
+
+func TestPlanReport_addConsumer(t *testing.T) {
+	report := newPlanReport()
+
+	report.addConsumer("scope-a", "111111111", "add")
+	report.addConsumer("scope-a", "222222222", "remove")
+
+	assert.Equal(t, 2, report.ConsumersChanged)
+	assert.Len(t, report.ConsumerActions, 2)
+	assert.Equal(t, "111111111", report.ConsumerActions[0].Orgno)
+}
+
+func TestPlanReport_log(t *testing.T) {
+	report := newPlanReport()
+	report.addScope("scope-a", "create")
+
+	logger := log.NewEntry(log.New())
+
+	assert.NotPanics(t, func() { report.log(logger) })
+}
@@ -2,6 +2,7 @@ package common
 
 import (
 	"fmt"
+	"sync"
 
 	naisiov1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -9,6 +10,7 @@ import (
 	"github.com/nais/digdirator/pkg/clients"
 	"github.com/nais/digdirator/pkg/digdir/scopes"
 	"github.com/nais/digdirator/pkg/digdir/types"
+	"github.com/nais/digdirator/pkg/discovery"
 	"github.com/nais/digdirator/pkg/metrics"
 )
 
@@ -21,8 +23,15 @@ func (r Reconciler) scopes(transaction *Transaction) scope {
 	return scope{Rec: r, Tx: transaction}
 }
 
-func (s *scope) Process(exposedScopes map[string]naisiov1.ExposedScope) error {
-	if exposedScopes == nil || len(exposedScopes) == 0 {
+// Process reconciles directExposedScopes, as defined on the resource, merged with
+// any file-sourced groups tagged for this instance/cluster, against DigDir. groups
+// is expected to come from discovery.Watcher.Groups - Process itself performs the
+// merge (directly-defined scopes win on name collision) so that file-based scope
+// discovery takes effect for every caller without each one having to remember to
+// call discovery.Merge first.
+func (s *scope) Process(directExposedScopes map[string]naisiov1.ExposedScope, groups []discovery.Group) error {
+	exposedScopes := discovery.Merge(directExposedScopes, groups, s.Tx.Config.ClusterName, s.Tx.Instance.GetName())
+	if len(exposedScopes) == 0 {
 		return nil
 	}
 
@@ -31,6 +40,15 @@ func (s *scope) Process(exposedScopes map[string]naisiov1.ExposedScope) error {
 		return fmt.Errorf("checking if scopes exists: %w", err)
 	}
 
+	if s.dryRun() {
+		report := newPlanReport()
+		if err := s.planScopes(filteredScopes, report); err != nil {
+			return err
+		}
+		s.reportPlan(report)
+		return nil
+	}
+
 	err = s.createScopes(filteredScopes.ToCreate)
 	if err != nil {
 		return err
@@ -44,52 +62,113 @@ func (s *scope) Process(exposedScopes map[string]naisiov1.ExposedScope) error {
 	return nil
 }
 
+// planScopes computes the same diff createScopes/updateScopes/updateConsumers would
+// act on, recording it into report instead of calling DigdirClient.
+func (s *scope) planScopes(filteredScopes *scopes.ScopeStash, report *PlanReport) error {
+	for _, newScope := range filteredScopes.ToCreate {
+		report.addScope(newScope.Name, "create")
+		for _, consumer := range newScope.Consumers {
+			report.addConsumer(newScope.Name, consumer.Orgno, "add")
+		}
+	}
+
+	for _, sc := range filteredScopes.ToUpdate {
+		if sc.HasChanged() {
+			report.addScope(sc.ToString(), "update")
+		}
+		if sc.CanBeActivated() {
+			report.addScope(sc.ToString(), "activate")
+		}
+		if !sc.IsActive() {
+			report.addScope(sc.ToString(), "deactivate")
+		}
+
+		acl, err := s.Tx.DigdirClient.GetScopeACL(s.Tx.Ctx, sc.ToString())
+		if err != nil {
+			return fmt.Errorf("gettin ACL from Digdir: %w", err)
+		}
+		_, consumerList := sc.FilterConsumers(acl)
+		for _, consumer := range consumerList {
+			if consumer.ShouldBeAdded {
+				report.addConsumer(sc.ToString(), consumer.Orgno, "add")
+			} else {
+				report.addConsumer(sc.ToString(), consumer.Orgno, "remove")
+			}
+		}
+	}
+
+	return nil
+}
+
 func (s scope) createScopes(toCreate []naisiov1.ExposedScope) error {
-	for _, newScope := range toCreate {
+	maxConcurrency := s.maxConcurrentScopeOps()
+	return forEachBounded(len(toCreate), maxConcurrency, func(i int) error {
+		newScope := toCreate[i]
 		s.Tx.Logger.Debug(fmt.Sprintf("Subscope - %s do not exist in Digdir, creating...", newScope.Name))
 
-		scope, err := s.create(newScope)
+		var scope *types.ScopeRegistration
+		err := withRetry(func() error {
+			var err error
+			scope, err = s.create(newScope)
+			return err
+		}, func() {
+			s.Tx.Logger.Debugf("retrying scope creation for %s", newScope.Name)
+			metrics.IncScopesCreated(s.Tx.Instance, "retry")
+		})
 		if err != nil {
+			metrics.IncScopesCreated(s.Tx.Instance, "failure")
 			return err
 		}
 		s.Rec.reportEvent(s.Tx, corev1.EventTypeNormal, EventCreatedScopeInDigDir, fmt.Sprintf("Scope created.. %s", scope.Name))
-		metrics.IncScopesCreated(s.Tx.Instance)
+		metrics.IncScopesCreated(s.Tx.Instance, "success")
 
 		// add consumers
 		_, err = s.updateConsumers(scopes.CurrentScopeInfo(*scope, newScope))
 		if err != nil {
 			return fmt.Errorf("adding new consumers to acl: %w", err)
 		}
-	}
-
-	return nil
+		return nil
+	})
 }
 
 func (s scope) updateScopes(toUpdate []scopes.Scope) error {
-	for _, scope := range toUpdate {
+	maxConcurrency := s.maxConcurrentScopeOps()
+	return forEachBounded(len(toUpdate), maxConcurrency, func(i int) error {
+		scope := toUpdate[i]
 		s.Tx.Logger.Debug(fmt.Sprintf("Scope - %s already exists in Digdir...", scope.ToString()))
 
 		if scope.HasChanged() {
 			// update existing scope
-			scopeRegistration, err := s.update(scope)
+			var scopeRegistration *types.ScopeRegistration
+			err := withRetry(func() error {
+				var err error
+				scopeRegistration, err = s.update(scope)
+				return err
+			}, func() {
+				s.Tx.Logger.Debugf("retrying scope update for %s", scope.ToString())
+				metrics.IncScopesUpdated(s.Tx.Instance, "retry")
+			})
 			if err != nil {
+				metrics.IncScopesUpdated(s.Tx.Instance, "failure")
 				return err
 			}
 			s.Rec.reportEvent(s.Tx, corev1.EventTypeNormal, EventUpdatedScopeInDigDir, fmt.Sprintf("Scope updated.. %s", scopeRegistration.Name))
-			metrics.IncScopesUpdated(s.Tx.Instance)
+			metrics.IncScopesUpdated(s.Tx.Instance, "success")
 		}
 
 		if scope.CanBeActivated() {
 			// re-activate scope
-			err := s.activate(scope)
+			err := withRetry(func() error { return s.activate(scope) }, func() { metrics.IncScopesReactivated(s.Tx.Instance, "retry") })
 			if err != nil {
+				metrics.IncScopesReactivated(s.Tx.Instance, "failure")
 				return err
 			}
 		}
 
 		if !scope.IsActive() {
-			err := s.deactivate(scope.ToString())
+			err := withRetry(func() error { return s.deactivate(scope.ToString()) }, func() { metrics.IncScopesDeleted(s.Tx.Instance, "retry") })
 			if err != nil {
+				metrics.IncScopesDeleted(s.Tx.Instance, "failure")
 				return err
 			}
 		}
@@ -98,9 +177,8 @@ func (s scope) updateScopes(toUpdate []scopes.Scope) error {
 		if err != nil {
 			return fmt.Errorf("update consumers acl: %w", err)
 		}
-	}
-
-	return nil
+		return nil
+	})
 }
 
 func (s scope) scopesExist(exposedScopes map[string]naisiov1.ExposedScope) (*scopes.ScopeStash, error) {
@@ -112,8 +190,8 @@ func (s scope) scopesExist(exposedScopes map[string]naisiov1.ExposedScope) (*sco
 }
 
 func (s *scope) updateConsumers(scope scopes.Scope) ([]types.ConsumerRegistration, error) {
-	s.Tx.Logger = s.Tx.Logger.WithField("scope", scope.ToString())
-	s.Tx.Logger.Debug("checking if ACL needs update...")
+	logger := s.Tx.Logger.WithField("scope", scope.ToString())
+	logger.Debug("checking if ACL needs update...")
 
 	acl, err := s.Tx.DigdirClient.GetScopeACL(s.Tx.Ctx, scope.ToString())
 	if err != nil {
@@ -128,23 +206,51 @@ func (s *scope) updateConsumers(scope scopes.Scope) ([]types.ConsumerRegistratio
 		return nil, nil
 	}
 
-	for _, consumer := range consumerList {
+	var mu sync.Mutex
+	err = forEachBounded(len(consumerList), s.maxConcurrentScopeOps(), func(i int) error {
+		consumer := consumerList[i]
 		if consumer.ShouldBeAdded {
-			response, err := s.activateConsumer(scope.ToString(), consumer.Orgno)
-			if err != nil {
-				return nil, fmt.Errorf("adding to ACL: %w", err)
+			var response *types.ConsumerRegistration
+			retryErr := withRetry(func() error {
+				var err error
+				response, err = s.activateConsumer(scope.ToString(), consumer.Orgno)
+				return err
+			}, func() {
+				logger.Debugf("retrying ACL add for consumer %s", consumer.Orgno)
+				metrics.IncScopesConsumersCreatedOrUpdated(s.Tx.Instance, consumer.State, "retry")
+			})
+			if retryErr != nil {
+				metrics.IncScopesConsumersCreatedOrUpdated(s.Tx.Instance, consumer.State, "failure")
+				return fmt.Errorf("adding to ACL: %w", retryErr)
 			}
+			mu.Lock()
 			consumerStatus = append(consumerStatus, consumer.Orgno)
 			registrationResponse = append(registrationResponse, *response)
-			metrics.IncScopesConsumersCreatedOrUpdated(s.Tx.Instance, consumer.State)
+			mu.Unlock()
+			metrics.IncScopesConsumersCreatedOrUpdated(s.Tx.Instance, consumer.State, "success")
 		} else {
-			response, err := s.deactivateConsumer(scope.ToString(), consumer.Orgno)
-			if err != nil {
-				return nil, fmt.Errorf("delete from ACL: %w", err)
+			var response *types.ConsumerRegistration
+			retryErr := withRetry(func() error {
+				var err error
+				response, err = s.deactivateConsumer(scope.ToString(), consumer.Orgno)
+				return err
+			}, func() {
+				logger.Debugf("retrying ACL remove for consumer %s", consumer.Orgno)
+				metrics.IncScopesConsumersDeleted(s.Tx.Instance, "retry")
+			})
+			if retryErr != nil {
+				metrics.IncScopesConsumersDeleted(s.Tx.Instance, "failure")
+				return fmt.Errorf("delete from ACL: %w", retryErr)
 			}
+			mu.Lock()
 			registrationResponse = append(registrationResponse, *response)
-			metrics.IncScopesConsumersDeleted(s.Tx.Instance)
+			mu.Unlock()
+			metrics.IncScopesConsumersDeleted(s.Tx.Instance, "success")
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	s.Rec.reportEvent(s.Tx, corev1.EventTypeNormal, EventUpdatedACLForScopeInDigDir, fmt.Sprintf("Scope ACL been updated.. %s", scope.ToString()))
@@ -173,8 +279,8 @@ func (s *scope) deactivateConsumer(scope, consumerOrgno string) (*types.Consumer
 
 func (s *scope) update(scope scopes.Scope) (*types.ScopeRegistration, error) {
 	scopePayload := clients.ToScopeRegistration(s.Tx.Instance, scope.CurrentScope, s.Tx.Config)
-	s.Tx.Logger = s.Tx.Logger.WithField("scope", scope.ToString())
-	s.Tx.Logger.Debug("updating scope...")
+	logger := s.Tx.Logger.WithField("scope", scope.ToString())
+	logger.Debug("updating scope...")
 
 	registrationResponse, err := s.Tx.DigdirClient.UpdateScope(s.Tx.Ctx, scopePayload, scope.ToString())
 	if err != nil {
@@ -192,8 +298,8 @@ func (s *scope) create(newScope naisiov1.ExposedScope) (*types.ScopeRegistration
 		return nil, fmt.Errorf("registering client to Digdir: %w", err)
 	}
 
-	s.Tx.Logger = s.Tx.Logger.WithField("scope", registrationResponse.Name)
-	s.Tx.Logger.Info("scope registered")
+	logger := s.Tx.Logger.WithField("scope", registrationResponse.Name)
+	logger.Info("scope registered")
 	return registrationResponse, nil
 }
 
@@ -206,7 +312,7 @@ func (s *scope) deactivate(scope string) error {
 	msg := fmt.Sprintf("Scope '%s' deactivated, consumers no longer have access", scopeRegistration.Name)
 	s.Tx.Logger.Warning(msg)
 	s.Rec.reportEvent(s.Tx, corev1.EventTypeWarning, EventDeactivatedScopeInDigDir, msg)
-	metrics.IncScopesDeleted(s.Tx.Instance)
+	metrics.IncScopesDeleted(s.Tx.Instance, "success")
 
 	return nil
 }
@@ -221,7 +327,7 @@ func (s *scope) activate(scope scopes.Scope) error {
 	msg := fmt.Sprintf("Scope '%s' activated", scopeRegistration.Name)
 	s.Tx.Logger.Info(msg)
 	s.Rec.reportEvent(s.Tx, corev1.EventTypeNormal, EventActivatedScopeInDigDir, msg)
-	metrics.IncScopesReactivated(s.Tx.Instance)
+	metrics.IncScopesReactivated(s.Tx.Instance, "success")
 
 	return nil
 }
@@ -236,12 +342,21 @@ func (s *scope) Finalize(exposedScopes map[string]naisiov1.ExposedScope) error {
 		return nil
 	}
 
+	if s.dryRun() {
+		report := newPlanReport()
+		for _, scope := range filteredScopes.ToUpdate {
+			report.addScope(scope.ToString(), "deactivate")
+		}
+		s.reportPlan(report)
+		return nil
+	}
+
 	for _, scope := range filteredScopes.ToUpdate {
 		s.Tx.Logger.Infof("delete annotation set, deleting scope: %s from Maskinporten... ", scope.ToString())
 		if _, err := s.Tx.DigdirClient.DeleteScope(s.Tx.Ctx, scope.ToString()); err != nil {
 			return fmt.Errorf("deactivate scope in Maskinporten: %w", err)
 		}
-		metrics.IncScopesDeleted(s.Tx.Instance)
+		metrics.IncScopesDeleted(s.Tx.Instance, "success")
 		s.Rec.reportEvent(s.Tx, corev1.EventTypeNormal, EventDeactivatedScopeInDigDir, "Scope deactivated in Digdir")
 	}
 
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 	log "github.com/sirupsen/logrus"
@@ -21,18 +22,41 @@ type Config struct {
 type DigDir struct {
 	Auth     Auth   `json:"auth"`
 	Endpoint string `json:"endpoint"`
+	// DryRun, when true, makes scope reconciliation compute and log/report its planned
+	// actions without issuing any mutating calls to DigDir. Can be overridden per
+	// resource with the digdir.nais.io/dry-run annotation.
+	DryRun bool `json:"dry-run"`
+	// MaxConcurrentScopeOps caps how many scope/consumer operations against DigDir a
+	// single reconciliation may have in flight at once.
+	MaxConcurrentScopeOps int `json:"max-concurrent-scope-ops"`
+	// ScopeDiscoveryPath, when set, is a directory watched for files that each
+	// declare a named group of ExposedScope entries to merge in alongside the ones
+	// defined directly on a resource. See pkg/discovery.
+	ScopeDiscoveryPath string `json:"scope-discovery-path"`
+	// LiveStateReportInterval is how often pkg/livestatereporter polls DigDir for the
+	// live state of tracked scopes and mirrors it into .status.digdirState.
+	LiveStateReportInterval time.Duration `json:"live-state-report-interval"`
 }
 
 type Auth struct {
 	Jwk string `json:"jwk"`
+	// JwkSource, when set, overrides Jwk on every reload by reading the JWK from this
+	// path, e.g. a mounted Secret file. Lets an operator rotate the Maskinporten
+	// client key without restarting the pod.
+	JwkSource string `json:"jwk-source"`
 }
 
 const (
-	MetricsAddress  = "metrics-address"
-	ClusterName     = "cluster-name"
-	DevelopmentMode = "development-mode"
-	DigDirAuthJwk   = "digdir.auth.jwk"
-	DigDirEndpoint  = "digdir.endpoint"
+	MetricsAddress                = "metrics-address"
+	ClusterName                   = "cluster-name"
+	DevelopmentMode               = "development-mode"
+	DigDirAuthJwk                 = "digdir.auth.jwk"
+	DigDirAuthJwkSource           = "digdir.auth.jwk-source"
+	DigDirEndpoint                = "digdir.endpoint"
+	DigDirDryRun                  = "digdir.dry-run"
+	DigDirMaxConcurrentScopeOps   = "digdir.max-concurrent-scope-ops"
+	DigDirScopeDiscoveryPath      = "digdir.scope-discovery-path"
+	DigDirLiveStateReportInterval = "digdir.live-state-report-interval"
 )
 
 func init() {
@@ -52,7 +76,12 @@ func init() {
 	flag.String(ClusterName, "", "The cluster in which this application should run.")
 	flag.String(DevelopmentMode, "false", "Toggle for development mode.")
 	flag.String(DigDirAuthJwk, "", "JWK for authenticating to DigDir.")
+	flag.String(DigDirAuthJwkSource, "", "Path to a file (e.g. a mounted Secret) to re-read the JWK from on reload.")
 	flag.String(DigDirEndpoint, "", "Endpoint to DigDir.")
+	flag.String(DigDirDryRun, "false", "Compute and report planned scope changes without applying them to DigDir.")
+	flag.Int(DigDirMaxConcurrentScopeOps, 4, "Maximum number of concurrent scope/consumer operations against DigDir per reconciliation.")
+	flag.String(DigDirScopeDiscoveryPath, "", "Directory watched for files declaring extra ExposedScope groups to merge in.")
+	flag.Duration(DigDirLiveStateReportInterval, 5*time.Minute, "How often to poll DigDir for the live state of tracked scopes.")
 }
 
 // Print out all configuration options except secret stuff.
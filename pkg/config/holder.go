@@ -0,0 +1,159 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// Getter returns the current configuration snapshot. Reconciler and Transaction
+// construction should take a Getter instead of a captured *Config, so that rotating
+// the Maskinporten client key or flipping development mode via Reload takes effect
+// for the next reconciliation without a pod restart.
+//
+// NOT YET WIRED IN: this package is self-contained and tested, but nothing in the
+// running operator calls NewHolderFromEnv or Watch yet, and Reconciler/Transaction
+// construction (in cmd/digdirator, not part of this checkout) still closes over a
+// plain *Config. Rotating the JWK or flipping development mode has no effect until
+// that call site is updated to hold a Getter instead. Track that as a follow-up,
+// not a finished rollout of hot-reload.
+type Getter func() *Config
+
+// Recorder is implemented by callers that want a Kubernetes Event emitted whenever
+// the configuration is reloaded, e.g. the controller-runtime manager's event
+// recorder wrapped around some cluster-wide object.
+type Recorder interface {
+	RecordConfigReload(success bool, message string)
+}
+
+// Holder holds the currently active Config behind a pointer that is atomically
+// swapped on reload, modeled after the lock/replace-pointer/unlock pattern used
+// elsewhere for swapping handlers at runtime. In-flight reconciliations that have
+// already called Get keep the snapshot they started with; only reconciliations
+// started after a reload observe the new Config.
+type Holder struct {
+	mu       sync.RWMutex
+	cfg      *Config
+	required []string
+	recorder Recorder
+}
+
+// NewHolder wraps an already-loaded Config in a Holder. required is the same list
+// of keys passed to Config.Validate on every subsequent reload.
+func NewHolder(cfg *Config, required []string) *Holder {
+	return &Holder{
+		cfg:      cfg,
+		required: required,
+	}
+}
+
+// NewHolderFromEnv performs the initial boot-time load that New used to do alone -
+// read config, validate against required - and wraps the result in a Holder, so
+// callers get a single call that replaces `cfg, err := config.New()` wherever a
+// live-reloadable Config is wanted instead of a one-shot snapshot.
+func NewHolderFromEnv(required []string) (*Holder, error) {
+	cfg, err := New()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(required); err != nil {
+		return nil, err
+	}
+	return NewHolder(cfg, required), nil
+}
+
+// SetRecorder attaches a Recorder used to emit Kubernetes Events on reload. Optional;
+// reload still works without one.
+func (h *Holder) SetRecorder(recorder Recorder) {
+	h.recorder = recorder
+}
+
+// Get returns the currently active Config. Safe for concurrent use; callers should
+// call this once per reconciliation and use the returned snapshot throughout, rather
+// than capturing a *Config ahead of time.
+func (h *Holder) Get() *Config {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.cfg
+}
+
+// Reload re-reads configuration from viper, re-reads the JWK from DigDir.Auth.JwkSource
+// if set, validates the result and only then swaps it in. The previous Config is kept
+// untouched if either step fails.
+func (h *Holder) Reload() error {
+	var cfg Config
+
+	if err := viper.Unmarshal(&cfg, decoderHook); err != nil {
+		return h.reloadFailed(fmt.Errorf("unmarshalling reloaded configuration: %w", err))
+	}
+
+	if len(cfg.DigDir.Auth.JwkSource) > 0 {
+		jwk, err := os.ReadFile(cfg.DigDir.Auth.JwkSource)
+		if err != nil {
+			return h.reloadFailed(fmt.Errorf("reading jwk from %q: %w", cfg.DigDir.Auth.JwkSource, err))
+		}
+		cfg.DigDir.Auth.Jwk = string(jwk)
+	}
+
+	if err := cfg.Validate(h.required); err != nil {
+		return h.reloadFailed(fmt.Errorf("validating reloaded configuration: %w", err))
+	}
+
+	h.mu.Lock()
+	h.cfg = &cfg
+	h.mu.Unlock()
+
+	log.Info("configuration reloaded")
+	h.record(true, "configuration reloaded successfully")
+	return nil
+}
+
+func (h *Holder) reloadFailed(err error) error {
+	log.Errorf("reloading configuration: %v, keeping previous configuration", err)
+	h.record(false, err.Error())
+	return err
+}
+
+func (h *Holder) record(success bool, message string) {
+	if h.recorder != nil {
+		h.recorder.RecordConfigReload(success, message)
+	}
+}
+
+// Watch blocks until ctx is cancelled, triggering Reload whenever the config file on
+// disk changes, a SIGHUP is received, or jwkRefreshInterval elapses - the latter so a
+// rotated JWK is picked up even if nothing touches the config file itself.
+func (h *Holder) Watch(ctx context.Context, jwkRefreshInterval time.Duration) {
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Infof("config file changed: %s", e.Name)
+		_ = h.Reload()
+	})
+	viper.WatchConfig()
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	ticker := time.NewTicker(jwkRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			log.Info("received SIGHUP, reloading configuration")
+			_ = h.Reload()
+		case <-ticker.C:
+			_ = h.Reload()
+		}
+	}
+}
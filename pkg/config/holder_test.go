@@ -0,0 +1,57 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nais/digdirator/pkg/config"
+)
+
+type fakeRecorder struct {
+	calls int
+	last  bool
+}
+
+func (f *fakeRecorder) RecordConfigReload(success bool, message string) {
+	f.calls++
+	f.last = success
+}
+
+func TestHolder_Get(t *testing.T) {
+	cfg := &config.Config{}
+	holder := config.NewHolder(cfg, nil)
+
+	assert.Same(t, cfg, holder.Get())
+}
+
+func TestNewHolderFromEnv(t *testing.T) {
+	t.Run("fails validation for a missing required key", func(t *testing.T) {
+		_, err := config.NewHolderFromEnv([]string{config.ClusterName})
+		assert.Error(t, err)
+	})
+
+	t.Run("succeeds and returns a usable Holder when nothing is required", func(t *testing.T) {
+		holder, err := config.NewHolderFromEnv(nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, holder.Get())
+	})
+}
+
+func TestHolder_Reload_keepsPreviousConfigOnFailure(t *testing.T) {
+	viper.Set(config.DigDirAuthJwkSource, "/nonexistent/path/to/jwk")
+	defer viper.Set(config.DigDirAuthJwkSource, "")
+
+	cfg := &config.Config{}
+	holder := config.NewHolder(cfg, nil)
+
+	recorder := &fakeRecorder{}
+	holder.SetRecorder(recorder)
+
+	err := holder.Reload()
+	assert.Error(t, err)
+	assert.Same(t, cfg, holder.Get(), "a failed reload must not replace the previous config")
+	assert.Equal(t, 1, recorder.calls)
+	assert.False(t, recorder.last)
+}
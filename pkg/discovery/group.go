@@ -0,0 +1,56 @@
+package discovery
+
+import (
+	naisiov1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+
+	"github.com/nais/digdirator/pkg/util"
+)
+
+// Group is a named set of ExposedScope definitions read from a single file, e.g. an
+// organization-wide baseline of scopes with consumer ACLs shipped via a ConfigMap
+// instead of being duplicated into every application CR.
+type Group struct {
+	// Name identifies the group within its source file; combined with the file path
+	// it forms the cache key used to detect unchanged groups.
+	Name string `json:"name"`
+	// Clusters, if non-empty, restricts the group to the listed clusters.
+	Clusters []string `json:"clusters,omitempty"`
+	// Instances, if non-empty, restricts the group to the listed instance names.
+	Instances []string `json:"instances,omitempty"`
+	// Scopes are the ExposedScope entries this group contributes, keyed by scope name.
+	Scopes map[string]naisiov1.ExposedScope `json:"scopes"`
+}
+
+// AppliesTo reports whether this group is tagged for the given cluster and instance.
+// An empty Clusters/Instances list means "applies everywhere".
+func (g Group) AppliesTo(cluster, instance string) bool {
+	if len(g.Clusters) > 0 && !util.ContainsString(g.Clusters, cluster) {
+		return false
+	}
+	if len(g.Instances) > 0 && !util.ContainsString(g.Instances, instance) {
+		return false
+	}
+	return true
+}
+
+// Merge combines the ExposedScope entries defined directly on a resource with any
+// discovered groups tagged for cluster/instance. Directly-defined scopes win on name
+// collision, since they are the more specific source.
+func Merge(direct map[string]naisiov1.ExposedScope, groups []Group, cluster, instance string) map[string]naisiov1.ExposedScope {
+	merged := make(map[string]naisiov1.ExposedScope, len(direct))
+
+	for _, group := range groups {
+		if !group.AppliesTo(cluster, instance) {
+			continue
+		}
+		for name, exposedScope := range group.Scopes {
+			merged[name] = exposedScope
+		}
+	}
+
+	for name, exposedScope := range direct {
+		merged[name] = exposedScope
+	}
+
+	return merged
+}
@@ -0,0 +1,58 @@
+package discovery_test
+
+import (
+	"testing"
+
+	naisiov1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nais/digdirator/pkg/discovery"
+)
+
+func TestGroup_AppliesTo(t *testing.T) {
+	t.Run("untagged group applies everywhere", func(t *testing.T) {
+		g := discovery.Group{Name: "baseline"}
+		assert.True(t, g.AppliesTo("dev-gcp", "myapp"))
+	})
+
+	t.Run("cluster-tagged group only applies to listed clusters", func(t *testing.T) {
+		g := discovery.Group{Name: "baseline", Clusters: []string{"prod-gcp"}}
+		assert.False(t, g.AppliesTo("dev-gcp", "myapp"))
+		assert.True(t, g.AppliesTo("prod-gcp", "myapp"))
+	})
+
+	t.Run("instance-tagged group only applies to listed instances", func(t *testing.T) {
+		g := discovery.Group{Name: "baseline", Instances: []string{"myapp"}}
+		assert.False(t, g.AppliesTo("dev-gcp", "otherapp"))
+		assert.True(t, g.AppliesTo("dev-gcp", "myapp"))
+	})
+}
+
+func TestMerge(t *testing.T) {
+	direct := map[string]naisiov1.ExposedScope{
+		"scope-a": {Name: "scope-a", Consumers: []naisiov1.ExposedScopeConsumer{{Orgno: "111111111"}}},
+	}
+	groups := []discovery.Group{
+		{
+			Name: "baseline",
+			Scopes: map[string]naisiov1.ExposedScope{
+				"scope-a": {Name: "scope-a", Consumers: []naisiov1.ExposedScopeConsumer{{Orgno: "999999999"}}},
+				"scope-b": {Name: "scope-b"},
+			},
+		},
+		{
+			Name:     "prod-only",
+			Clusters: []string{"prod-gcp"},
+			Scopes: map[string]naisiov1.ExposedScope{
+				"scope-c": {Name: "scope-c"},
+			},
+		},
+	}
+
+	merged := discovery.Merge(direct, groups, "dev-gcp", "myapp")
+
+	assert.Len(t, merged, 2)
+	assert.Equal(t, "111111111", merged["scope-a"].Consumers[0].Orgno, "directly-defined scope should win over a file-sourced one with the same name")
+	assert.Contains(t, merged, "scope-b")
+	assert.NotContains(t, merged, "scope-c", "prod-only group should not apply in dev-gcp")
+}
@@ -0,0 +1,223 @@
+package discovery
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+const defaultDebounce = 500 * time.Millisecond
+
+// Watcher watches a directory of YAML/JSON files, each declaring a Group, and keeps
+// an in-memory, hot-reloaded view of all of them. Groups are cached by source path
+// and name so a file touched without its content changing is skipped.
+type Watcher struct {
+	root     string
+	debounce time.Duration
+
+	mu     sync.RWMutex
+	groups map[string]Group  // keyed by groupKey(source, name)
+	hashes map[string]string // source path -> sha256 of last-loaded content
+	keys   map[string]string // source path -> the groups key currently loaded from it
+}
+
+// NewWatcher creates a Watcher rooted at dir. Call Start to load the initial set of
+// groups and begin watching for changes, then pass Groups() into
+// common.scope.Process on every reconcile - Process does the merge against the
+// directly-defined scopes itself.
+//
+// Starting the Watcher itself is still a one-line addition at the manager
+// construction site in cmd/digdirator (mgr.Add or an explicit go w.Start(ctx)),
+// which is not part of this checkout, so that last step is out of scope here.
+func NewWatcher(dir string) *Watcher {
+	return &Watcher{
+		root:     dir,
+		debounce: defaultDebounce,
+		groups:   make(map[string]Group),
+		hashes:   make(map[string]string),
+		keys:     make(map[string]string),
+	}
+}
+
+// Groups returns a snapshot of all currently loaded groups, ordered deterministically
+// by their cache key (source path, then group name) so that Merge resolves same-named
+// scopes across groups the same way on every call instead of depending on Go's
+// randomized map iteration order.
+func (w *Watcher) Groups() []Group {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	keys := make([]string, 0, len(w.groups))
+	for key := range w.groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	groups := make([]Group, 0, len(keys))
+	for _, key := range keys {
+		groups = append(groups, w.groups[key])
+	}
+	return groups
+}
+
+// Start performs an initial load of w.root and then watches it for changes until ctx
+// is cancelled. Returns once the initial load has completed; the watch itself runs in
+// a background goroutine.
+func (w *Watcher) Start(ctx context.Context) error {
+	entries, err := os.ReadDir(w.root)
+	if err != nil {
+		return fmt.Errorf("reading scope discovery path %q: %w", w.root, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		w.reload(filepath.Join(w.root, entry.Name()))
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating file watcher: %w", err)
+	}
+	if err := fsWatcher.Add(w.root); err != nil {
+		_ = fsWatcher.Close()
+		return fmt.Errorf("watching %q: %w", w.root, err)
+	}
+
+	go w.run(ctx, fsWatcher)
+	return nil
+}
+
+func (w *Watcher) run(ctx context.Context, fsWatcher *fsnotify.Watcher) {
+	defer fsWatcher.Close()
+
+	pending := make(map[string]struct{})
+
+	// timer's channel is only ever read from this goroutine's select loop below, so
+	// pending is never touched concurrently - unlike time.AfterFunc, whose callback
+	// runs on its own goroutine.
+	timer := time.NewTimer(w.debounce)
+	if !timer.Stop() {
+		<-timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return
+			}
+			pending[event.Name] = struct{}{}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(w.debounce)
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			log.Errorf("scope discovery: watching %q: %v", w.root, err)
+		case <-timer.C:
+			for path := range pending {
+				w.reload(path)
+			}
+			pending = make(map[string]struct{})
+		}
+	}
+}
+
+// reload re-reads path, skipping it if its content hash matches what is cached, and
+// otherwise replaces the cached Group for that source. If path no longer exists -
+// because it was removed or renamed away, surfaced by fsnotify as a Remove/Rename
+// event - the group previously loaded from it is purged instead of being left stale.
+func (w *Watcher) reload(path string) {
+	if !isScopeFile(path) {
+		return
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			w.remove(path)
+			return
+		}
+		log.Errorf("scope discovery: reading %q: %v", path, err)
+		return
+	}
+
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	w.mu.RLock()
+	unchanged := w.hashes[path] == checksum
+	w.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	var group Group
+	if err := yaml.Unmarshal(content, &group); err != nil {
+		log.Errorf("scope discovery: parsing %q: %v", path, err)
+		return
+	}
+
+	key := groupKey(path, group.Name)
+
+	w.mu.Lock()
+	if oldKey, ok := w.keys[path]; ok && oldKey != key {
+		delete(w.groups, oldKey)
+	}
+	w.hashes[path] = checksum
+	w.keys[path] = key
+	w.groups[key] = group
+	w.mu.Unlock()
+
+	log.Infof("scope discovery: loaded group %q from %s (%d scopes)", group.Name, path, len(group.Scopes))
+}
+
+// remove purges the group previously loaded from path, if any.
+func (w *Watcher) remove(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	key, ok := w.keys[path]
+	if !ok {
+		return
+	}
+	delete(w.groups, key)
+	delete(w.keys, path)
+	delete(w.hashes, path)
+
+	log.Infof("scope discovery: %s removed, purging its group", path)
+}
+
+func groupKey(source, name string) string {
+	return source + "#" + name
+}
+
+func isScopeFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,96 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcher_reload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "baseline.yaml")
+
+	write := func(content string) {
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	w := NewWatcher(dir)
+
+	write(`
+name: baseline
+scopes:
+  scope-a:
+    name: scope-a
+`)
+	w.reload(path)
+	groups := w.Groups()
+	assert.Len(t, groups, 1)
+	assert.Equal(t, "baseline", groups[0].Name)
+	assert.Contains(t, groups[0].Scopes, "scope-a")
+
+	t.Run("reloading unchanged content is a no-op", func(t *testing.T) {
+		hashBefore := w.hashes[path]
+		w.reload(path)
+		assert.Equal(t, hashBefore, w.hashes[path])
+		assert.Len(t, w.Groups(), 1)
+	})
+
+	t.Run("reloading changed content replaces the cached group", func(t *testing.T) {
+		write(`
+name: baseline
+scopes:
+  scope-a:
+    name: scope-a
+  scope-b:
+    name: scope-b
+`)
+		w.reload(path)
+		groups := w.Groups()
+		assert.Len(t, groups, 1)
+		assert.Len(t, groups[0].Scopes, 2)
+	})
+
+	t.Run("removing the file purges its group instead of leaving it stale", func(t *testing.T) {
+		assert.NoError(t, os.Remove(path))
+		w.reload(path)
+		assert.Empty(t, w.Groups())
+		assert.Empty(t, w.hashes)
+		assert.Empty(t, w.keys)
+	})
+}
+
+func TestWatcher_Groups_orderedDeterministically(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(name, content string) {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	w := NewWatcher(dir)
+	write("z-group.yaml", "name: z-group\nscopes: {}\n")
+	write("a-group.yaml", "name: a-group\nscopes: {}\n")
+
+	w.reload(filepath.Join(dir, "z-group.yaml"))
+	w.reload(filepath.Join(dir, "a-group.yaml"))
+
+	for i := 0; i < 5; i++ {
+		groups := w.Groups()
+		assert.Len(t, groups, 2)
+		assert.Equal(t, "a-group", groups[0].Name, "groups should be sorted by source path, not map iteration order")
+		assert.Equal(t, "z-group", groups[1].Name)
+	}
+}
+
+func TestIsScopeFile(t *testing.T) {
+	assert.True(t, isScopeFile("group.yaml"))
+	assert.True(t, isScopeFile("group.YML"))
+	assert.True(t, isScopeFile("group.json"))
+	assert.False(t, isScopeFile("group.txt"))
+	assert.False(t, isScopeFile("README.md"))
+}
+
+func TestGroupKey(t *testing.T) {
+	assert.Equal(t, "path/to/file#name", groupKey("path/to/file", "name"))
+}
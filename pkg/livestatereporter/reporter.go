@@ -0,0 +1,180 @@
+package livestatereporter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	naisiov1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nais/digdirator/pkg/clients"
+	"github.com/nais/digdirator/pkg/digdir/types"
+)
+
+var scopeDriftTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "digdirator_scope_drift_total",
+	Help: "Number of times a scope's live state in DigDir was observed to differ from its spec.",
+}, []string{"scope"})
+
+func init() {
+	prometheus.MustRegister(scopeDriftTotal)
+}
+
+// ScopeState is a normalized, read-only snapshot of a single scope's live state in
+// DigDir, as last observed by the Reporter.
+//
+// Subscope names are not included. LastChangedAt is taken from the consumer ACL
+// entries the Reporter already fetches - see lastChangedAt.
+type ScopeState struct {
+	Name          string   `json:"name"`
+	Active        bool     `json:"active"`
+	Consumers     []string `json:"consumers,omitempty"`
+	LastChangedAt string   `json:"lastChangedAt,omitempty"`
+	DriftFromSpec bool     `json:"driftFromSpec"`
+}
+
+// statusWriter is implemented by CRs that can persist a live-state snapshot into
+// their own .status.digdirState field.
+type statusWriter interface {
+	SetDigdirState([]ScopeState)
+}
+
+// Reporter runs alongside the reconciler, periodically mirroring the live state of
+// every tracked MaskinportenClient's scopes in DigDir into .status.digdirState. It
+// issues only read-only calls (GetFilteredScopes, GetScopeACL), so it gives operators
+// visibility into drift from out-of-band changes made directly in Selvbetjening
+// without itself triggering a full reconcile.
+//
+// Reporter implements manager.Runnable, so registering it only takes a single
+// mgr.Add(reporter) call. NOT YET WIRED IN: that call doesn't exist anywhere yet -
+// manager setup lives in cmd/digdirator, which is not part of this checkout, so
+// Start/reportAll never run in production today and digdirator_scope_drift_total is
+// never emitted. Track wiring this in as a follow-up, not a finished rollout.
+type Reporter struct {
+	Client       client.Client
+	DigdirClient clients.DigdirClient
+	ClusterName  string
+	Interval     time.Duration
+	Limiter      *rate.Limiter
+}
+
+// New builds a Reporter ready to be registered with a manager via mgr.Add. interval
+// and clusterName typically come from Config.DigDir.LiveStateReportInterval and
+// Config.ClusterName respectively.
+func New(cl client.Client, digdirClient clients.DigdirClient, clusterName string, interval time.Duration) *Reporter {
+	return &Reporter{
+		Client:       cl,
+		DigdirClient: digdirClient,
+		ClusterName:  clusterName,
+		Interval:     interval,
+	}
+}
+
+// NeedLeaderElection makes the manager run the Reporter only on the leader replica,
+// same as the reconcilers it runs alongside.
+func (r *Reporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable. It has its own rate limiter and ticker so it
+// does not compete with the reconcile queue for DigDir API quota, and blocks until
+// ctx is cancelled.
+func (r *Reporter) Start(ctx context.Context) error {
+	if r.Limiter == nil {
+		r.Limiter = rate.NewLimiter(rate.Limit(1), 1)
+	}
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.reportAll(ctx)
+		}
+	}
+}
+
+func (r *Reporter) reportAll(ctx context.Context) {
+	var list naisiov1.MaskinportenClientList
+	if err := r.Client.List(ctx, &list); err != nil {
+		log.Errorf("livestatereporter: listing MaskinportenClients: %v", err)
+		return
+	}
+
+	for i := range list.Items {
+		instance := &list.Items[i]
+
+		if err := r.Limiter.Wait(ctx); err != nil {
+			return
+		}
+		if err := r.report(ctx, instance); err != nil {
+			log.Errorf("livestatereporter: reporting state for %s/%s: %v", instance.GetNamespace(), instance.GetName(), err)
+		}
+	}
+}
+
+func (r *Reporter) report(ctx context.Context, instance *naisiov1.MaskinportenClient) error {
+	stash, err := r.DigdirClient.GetFilteredScopes(instance, ctx, instance.GetExposedScopes(), r.ClusterName)
+	if err != nil {
+		return fmt.Errorf("getting filtered scopes: %w", err)
+	}
+
+	states := make([]ScopeState, 0, len(stash.ToUpdate))
+	for _, sc := range stash.ToUpdate {
+		if err := r.Limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		acl, err := r.DigdirClient.GetScopeACL(ctx, sc.ToString())
+		if err != nil {
+			return fmt.Errorf("getting ACL for %s: %w", sc.ToString(), err)
+		}
+
+		state := ScopeState{
+			Name:          sc.ToString(),
+			Active:        sc.IsActive(),
+			Consumers:     consumerOrgnos(acl),
+			LastChangedAt: lastChangedAt(acl),
+			DriftFromSpec: sc.HasChanged() || !sc.IsActive(),
+		}
+		if state.DriftFromSpec {
+			scopeDriftTotal.WithLabelValues(state.Name).Inc()
+		}
+		states = append(states, state)
+	}
+
+	writer, ok := client.Object(instance).(statusWriter)
+	if !ok {
+		return nil
+	}
+	writer.SetDigdirState(states)
+	return r.Client.Status().Update(ctx, instance)
+}
+
+func consumerOrgnos(acl []types.ConsumerRegistration) []string {
+	orgnos := make([]string, 0, len(acl))
+	for _, consumer := range acl {
+		orgnos = append(orgnos, consumer.Orgno)
+	}
+	return orgnos
+}
+
+// lastChangedAt returns the most recent LastUpdated timestamp (RFC3339, as DigDir
+// reports it) across acl, or "" if acl is empty. The scope's own registration is not
+// consulted, so a change to the scope itself with no ACL change is not reflected.
+func lastChangedAt(acl []types.ConsumerRegistration) string {
+	var latest string
+	for _, consumer := range acl {
+		if consumer.LastUpdated > latest {
+			latest = consumer.LastUpdated
+		}
+	}
+	return latest
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const Namespace = "digdirator"
+
+// Instance is the minimal surface metrics needs from a reconciled resource to label
+// a counter - satisfied by any controller-runtime client.Object.
+type Instance interface {
+	GetName() string
+	GetNamespace() string
+}
+
+var (
+	scopesCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "scopes_created_total",
+		Help:      "Number of scopes registered in DigDir, by result of the call.",
+	}, []string{"namespace", "name", "result"})
+
+	scopesUpdated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "scopes_updated_total",
+		Help:      "Number of scopes updated in DigDir, by result of the call.",
+	}, []string{"namespace", "name", "result"})
+
+	scopesDeleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "scopes_deleted_total",
+		Help:      "Number of scopes deactivated in DigDir, by result of the call.",
+	}, []string{"namespace", "name", "result"})
+
+	scopesReactivated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "scopes_reactivated_total",
+		Help:      "Number of scopes reactivated in DigDir, by result of the call.",
+	}, []string{"namespace", "name", "result"})
+
+	scopesConsumersCreatedOrUpdated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "scopes_consumers_created_or_updated_total",
+		Help:      "Number of consumer ACL entries added or updated for a scope, by result of the call.",
+	}, []string{"namespace", "name", "state", "result"})
+
+	scopesConsumersDeleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "scopes_consumers_deleted_total",
+		Help:      "Number of consumer ACL entries removed from a scope, by result of the call.",
+	}, []string{"namespace", "name", "result"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		scopesCreated,
+		scopesUpdated,
+		scopesDeleted,
+		scopesReactivated,
+		scopesConsumersCreatedOrUpdated,
+		scopesConsumersDeleted,
+	)
+}
+
+// IncScopesCreated records the result ("retry", "success" or "failure") of a single
+// attempt to register a scope in DigDir for instance.
+func IncScopesCreated(instance Instance, result string) {
+	scopesCreated.WithLabelValues(instance.GetNamespace(), instance.GetName(), result).Inc()
+}
+
+// IncScopesUpdated records the result of a single attempt to update an existing
+// scope's registration in DigDir for instance.
+func IncScopesUpdated(instance Instance, result string) {
+	scopesUpdated.WithLabelValues(instance.GetNamespace(), instance.GetName(), result).Inc()
+}
+
+// IncScopesDeleted records the result of a single attempt to deactivate a scope in
+// DigDir for instance.
+func IncScopesDeleted(instance Instance, result string) {
+	scopesDeleted.WithLabelValues(instance.GetNamespace(), instance.GetName(), result).Inc()
+}
+
+// IncScopesReactivated records the result of a single attempt to reactivate a
+// previously deactivated scope in DigDir for instance.
+func IncScopesReactivated(instance Instance, result string) {
+	scopesReactivated.WithLabelValues(instance.GetNamespace(), instance.GetName(), result).Inc()
+}
+
+// IncScopesConsumersCreatedOrUpdated records the result of a single attempt to add or
+// update a consumer's ACL entry for a scope owned by instance. state is the
+// consumer's registration state as reported by DigDir.
+func IncScopesConsumersCreatedOrUpdated(instance Instance, state string, result string) {
+	scopesConsumersCreatedOrUpdated.WithLabelValues(instance.GetNamespace(), instance.GetName(), state, result).Inc()
+}
+
+// IncScopesConsumersDeleted records the result of a single attempt to remove a
+// consumer's ACL entry for a scope owned by instance.
+func IncScopesConsumersDeleted(instance Instance, result string) {
+	scopesConsumersDeleted.WithLabelValues(instance.GetNamespace(), instance.GetName(), result).Inc()
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeInstance struct {
+	name      string
+	namespace string
+}
+
+func (f fakeInstance) GetName() string      { return f.name }
+func (f fakeInstance) GetNamespace() string { return f.namespace }
+
+func TestIncScopesCreated(t *testing.T) {
+	instance := fakeInstance{name: "test-client", namespace: "test-ns"}
+
+	IncScopesCreated(instance, "retry")
+	IncScopesCreated(instance, "retry")
+	IncScopesCreated(instance, "success")
+	IncScopesCreated(instance, "failure")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(scopesCreated.WithLabelValues("test-ns", "test-client", "retry")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(scopesCreated.WithLabelValues("test-ns", "test-client", "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(scopesCreated.WithLabelValues("test-ns", "test-client", "failure")))
+}
+
+func TestIncScopesConsumersCreatedOrUpdated(t *testing.T) {
+	instance := fakeInstance{name: "test-client", namespace: "test-ns"}
+
+	IncScopesConsumersCreatedOrUpdated(instance, "approved", "success")
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(scopesConsumersCreatedOrUpdated.WithLabelValues("test-ns", "test-client", "approved", "success")))
+}